@@ -0,0 +1,132 @@
+package nodeenv
+
+import (
+	"fmt"
+	"io"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/apiserver/pkg/admission"
+)
+
+// PluginName is the name this plugin is registered under.
+const PluginName = "openshift.io/ProjectNodeSelector"
+
+// Register registers the node environment admission plugin.
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName, func(io.Reader) (admission.Interface, error) {
+		return NewProjectNodeSelector(), nil
+	})
+}
+
+// projectNodeSelectorGetter fetches the raw, already-validated node selector
+// annotation for a namespace. It is satisfied by the project cache.
+type projectNodeSelectorGetter interface {
+	NodeSelectorForNamespace(name string) (string, error)
+}
+
+// WantsProjectNodeSelectorGetter is implemented by admission plugins that
+// need a projectNodeSelectorGetter. PluginInitializer wires it in after
+// construction, before ValidateInitialization is called.
+type WantsProjectNodeSelectorGetter interface {
+	SetProjectNodeSelectorGetter(projectNodeSelectorGetter)
+	admission.InitializationValidator
+}
+
+var _ WantsProjectNodeSelectorGetter = &podNodeSelector{}
+
+// PluginInitializer wires a projectNodeSelectorGetter into admission plugins
+// that want one. Master startup registers it alongside the other
+// admission.PluginInitializers so podNodeSelector doesn't need bespoke
+// construction wiring.
+type PluginInitializer struct {
+	Getter projectNodeSelectorGetter
+}
+
+// Initialize implements admission.PluginInitializer.
+func (i PluginInitializer) Initialize(plugin admission.Interface) {
+	if wants, ok := plugin.(WantsProjectNodeSelectorGetter); ok {
+		wants.SetProjectNodeSelectorGetter(i.Getter)
+	}
+}
+
+// podNodeSelector merges a project's default node selector into every
+// incoming pod. It is the enforcement half of the Project node selector
+// feature implemented by proxy.REST: a pod whose own selector sets a different
+// value for a key the project's selector also sets is rejected rather than
+// silently overridden.
+type podNodeSelector struct {
+	*admission.Handler
+	selectors projectNodeSelectorGetter
+}
+
+var _ admission.MutationInterface = &podNodeSelector{}
+var _ admission.ValidationInterface = &podNodeSelector{}
+
+// NewProjectNodeSelector creates a new podNodeSelector admission plugin.
+func NewProjectNodeSelector() admission.Interface {
+	return &podNodeSelector{
+		Handler: admission.NewHandler(admission.Create),
+	}
+}
+
+func (p *podNodeSelector) Admit(a admission.Attributes, o admission.ObjectInterfaces) error {
+	return p.admit(a)
+}
+
+func (p *podNodeSelector) Validate(a admission.Attributes, o admission.ObjectInterfaces) error {
+	return p.admit(a)
+}
+
+func (p *podNodeSelector) admit(a admission.Attributes) error {
+	if a.GetResource().GroupResource() != kapi.Resource("pods") || a.GetSubresource() != "" {
+		return nil
+	}
+	pod, ok := a.GetObject().(*kapi.Pod)
+	if !ok {
+		return nil
+	}
+
+	projectSelectorStr, err := p.selectors.NodeSelectorForNamespace(a.GetNamespace())
+	if err != nil {
+		return admission.NewForbidden(a, err)
+	}
+	if len(projectSelectorStr) == 0 {
+		return nil
+	}
+	projectSelector, err := labels.ConvertSelectorToLabelsMap(projectSelectorStr)
+	if err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("project %s has an invalid node selector: %v", a.GetNamespace(), err))
+	}
+
+	for k, projectValue := range projectSelector {
+		if podValue, exists := pod.Spec.NodeSelector[k]; exists && podValue != projectValue {
+			return kerrors.NewForbidden(a.GetResource().GroupResource(), pod.Name, fmt.Errorf("pod node selector %s=%s conflicts with project node selector %s=%s", k, podValue, k, projectValue))
+		}
+	}
+
+	merged := make(map[string]string, len(pod.Spec.NodeSelector)+len(projectSelector))
+	for k, v := range pod.Spec.NodeSelector {
+		merged[k] = v
+	}
+	for k, v := range projectSelector {
+		merged[k] = v
+	}
+	pod.Spec.NodeSelector = merged
+	return nil
+}
+
+// ValidateInitialization ensures the namespace-to-selector lookup was wired up.
+func (p *podNodeSelector) ValidateInitialization() error {
+	if p.selectors == nil {
+		return fmt.Errorf("%s needs a project node selector getter", PluginName)
+	}
+	return nil
+}
+
+// SetProjectNodeSelectorGetter wires the namespace-to-selector lookup used at
+// admission time.
+func (p *podNodeSelector) SetProjectNodeSelectorGetter(selectors projectNodeSelectorGetter) {
+	p.selectors = selectors
+}
@@ -0,0 +1,77 @@
+package nodeenv
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/admission"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+)
+
+type fakeSelectorGetter struct {
+	selector string
+	err      error
+}
+
+func (f *fakeSelectorGetter) NodeSelectorForNamespace(name string) (string, error) {
+	return f.selector, f.err
+}
+
+func newPodAttributes(pod *kapi.Pod, namespace string) admission.Attributes {
+	return admission.NewAttributesRecord(pod, nil, kapi.Kind("Pod").WithVersion("v1"), namespace, pod.Name, kapi.Resource("pods").WithVersion("v1"), "", admission.Create, nil)
+}
+
+func TestAdmitMergesProjectSelector(t *testing.T) {
+	p := &podNodeSelector{Handler: admission.NewHandler(admission.Create), selectors: &fakeSelectorGetter{selector: "region=east"}}
+	pod := &kapi.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1"},
+		Spec:       kapi.PodSpec{NodeSelector: map[string]string{"disk": "ssd"}},
+	}
+
+	if err := p.Admit(newPodAttributes(pod, "ns"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"disk": "ssd", "region": "east"}
+	if len(pod.Spec.NodeSelector) != len(want) {
+		t.Fatalf("expected merged selector %v, got %v", want, pod.Spec.NodeSelector)
+	}
+	for k, v := range want {
+		if pod.Spec.NodeSelector[k] != v {
+			t.Errorf("expected %s=%s, got %v", k, v, pod.Spec.NodeSelector)
+		}
+	}
+}
+
+func TestAdmitRejectsConflictingSelector(t *testing.T) {
+	p := &podNodeSelector{Handler: admission.NewHandler(admission.Create), selectors: &fakeSelectorGetter{selector: "region=east"}}
+	pod := &kapi.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1"},
+		Spec:       kapi.PodSpec{NodeSelector: map[string]string{"region": "west"}},
+	}
+
+	if err := p.Admit(newPodAttributes(pod, "ns"), nil); err == nil {
+		t.Fatalf("expected conflicting pod node selector to be rejected")
+	}
+}
+
+func TestValidateInitializationRequiresGetter(t *testing.T) {
+	p := &podNodeSelector{Handler: admission.NewHandler(admission.Create)}
+	if err := p.ValidateInitialization(); err == nil {
+		t.Errorf("expected ValidateInitialization to fail without a getter")
+	}
+
+	p.SetProjectNodeSelectorGetter(&fakeSelectorGetter{})
+	if err := p.ValidateInitialization(); err != nil {
+		t.Errorf("expected ValidateInitialization to pass once a getter is set, got %v", err)
+	}
+}
+
+func TestPluginInitializerWiresGetter(t *testing.T) {
+	p := NewProjectNodeSelector()
+	getter := &fakeSelectorGetter{selector: "region=east"}
+	PluginInitializer{Getter: getter}.Initialize(p)
+
+	if err := p.(WantsProjectNodeSelectorGetter).ValidateInitialization(); err != nil {
+		t.Errorf("expected plugin to be initialized after PluginInitializer.Initialize, got %v", err)
+	}
+}
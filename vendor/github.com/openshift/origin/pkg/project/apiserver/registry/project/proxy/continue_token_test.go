@@ -0,0 +1,21 @@
+package proxy
+
+import "testing"
+
+func TestContinueTokenRoundTrip(t *testing.T) {
+	encoded := encodeContinueToken("123", "last-name")
+
+	token, err := decodeContinueToken(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.ResourceVersion != "123" || token.LastName != "last-name" {
+		t.Errorf("unexpected round trip: %+v", token)
+	}
+}
+
+func TestDecodeContinueTokenRejectsGarbage(t *testing.T) {
+	if _, err := decodeContinueToken("not-base64!!"); err == nil {
+		t.Errorf("expected an invalid continue token to fail to decode")
+	}
+}
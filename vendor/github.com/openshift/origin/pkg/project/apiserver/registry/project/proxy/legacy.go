@@ -0,0 +1,158 @@
+package proxy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+	kcoreclient "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/typed/core/internalversion"
+
+	projectauth "github.com/openshift/origin/pkg/project/auth"
+	projectcache "github.com/openshift/origin/pkg/project/cache"
+)
+
+// NewLegacyREST adapts NewREST for call sites that still hold an internal
+// clientset NamespaceInterface. It exists only to keep pre-migration
+// callers compiling while they move to an external corev1 client; new code
+// should obtain one and call NewREST directly.
+//
+// Deprecated: call NewREST with a corev1client.NamespaceInterface instead.
+func NewLegacyREST(client kcoreclient.NamespaceInterface, lister projectauth.Lister, authCache *projectauth.AuthorizationCache, projectCache *projectcache.ProjectCache, defaultNodeSelector string) (*REST, error) {
+	return NewREST(&internalNamespaceClient{client}, lister, authCache, projectCache, defaultNodeSelector)
+}
+
+// internalNamespaceClient implements corev1client.NamespaceInterface on top
+// of the internal clientset, converting to and from external Namespace
+// objects field-by-field at each call that takes or returns a Namespace.
+// Watch is the exception: it returns the internal client's watch as is, so
+// its events carry internal kapi.Namespace objects.
+type internalNamespaceClient struct {
+	client kcoreclient.NamespaceInterface
+}
+
+var _ corev1client.NamespaceInterface = &internalNamespaceClient{}
+
+func toInternalNamespace(namespace *corev1.Namespace) *kapi.Namespace {
+	return &kapi.Namespace{
+		ObjectMeta: namespace.ObjectMeta,
+		Spec: kapi.NamespaceSpec{
+			Finalizers: toInternalFinalizers(namespace.Spec.Finalizers),
+		},
+		Status: kapi.NamespaceStatus{
+			Phase: kapi.NamespacePhase(namespace.Status.Phase),
+		},
+	}
+}
+
+// toExternalNamespaceList converts an internal-typed NamespaceList, such as
+// the one projectauth.Lister still produces, to the external type this
+// storage operates on.
+func toExternalNamespaceList(list *kapi.NamespaceList) *corev1.NamespaceList {
+	external := &corev1.NamespaceList{ListMeta: list.ListMeta}
+	for i := range list.Items {
+		external.Items = append(external.Items, *toExternalNamespace(&list.Items[i]))
+	}
+	return external
+}
+
+func toExternalNamespace(namespace *kapi.Namespace) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: namespace.ObjectMeta,
+		Spec: corev1.NamespaceSpec{
+			Finalizers: toExternalFinalizers(namespace.Spec.Finalizers),
+		},
+		Status: corev1.NamespaceStatus{
+			Phase: corev1.NamespacePhase(namespace.Status.Phase),
+		},
+	}
+}
+
+func toInternalFinalizers(in []corev1.FinalizerName) []kapi.FinalizerName {
+	out := make([]kapi.FinalizerName, len(in))
+	for i, f := range in {
+		out[i] = kapi.FinalizerName(f)
+	}
+	return out
+}
+
+func toExternalFinalizers(in []kapi.FinalizerName) []corev1.FinalizerName {
+	out := make([]corev1.FinalizerName, len(in))
+	for i, f := range in {
+		out[i] = corev1.FinalizerName(f)
+	}
+	return out
+}
+
+func (a *internalNamespaceClient) Create(namespace *corev1.Namespace) (*corev1.Namespace, error) {
+	out, err := a.client.Create(toInternalNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	return toExternalNamespace(out), nil
+}
+
+func (a *internalNamespaceClient) Update(namespace *corev1.Namespace) (*corev1.Namespace, error) {
+	out, err := a.client.Update(toInternalNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	return toExternalNamespace(out), nil
+}
+
+func (a *internalNamespaceClient) UpdateStatus(namespace *corev1.Namespace) (*corev1.Namespace, error) {
+	out, err := a.client.UpdateStatus(toInternalNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	return toExternalNamespace(out), nil
+}
+
+func (a *internalNamespaceClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return a.client.Delete(name, options)
+}
+
+func (a *internalNamespaceClient) DeleteCollection(options *metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	return a.client.DeleteCollection(options, listOptions)
+}
+
+func (a *internalNamespaceClient) Get(name string, options metav1.GetOptions) (*corev1.Namespace, error) {
+	out, err := a.client.Get(name, options)
+	if err != nil {
+		return nil, err
+	}
+	return toExternalNamespace(out), nil
+}
+
+func (a *internalNamespaceClient) List(opts metav1.ListOptions) (*corev1.NamespaceList, error) {
+	out, err := a.client.List(opts)
+	if err != nil {
+		return nil, err
+	}
+	list := &corev1.NamespaceList{ListMeta: out.ListMeta}
+	for i := range out.Items {
+		list.Items = append(list.Items, *toExternalNamespace(&out.Items[i]))
+	}
+	return list, nil
+}
+
+func (a *internalNamespaceClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return a.client.Watch(opts)
+}
+
+func (a *internalNamespaceClient) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (*corev1.Namespace, error) {
+	out, err := a.client.Patch(name, pt, data, subresources...)
+	if err != nil {
+		return nil, err
+	}
+	return toExternalNamespace(out), nil
+}
+
+func (a *internalNamespaceClient) Finalize(namespace *corev1.Namespace) (*corev1.Namespace, error) {
+	out, err := a.client.Finalize(toInternalNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	return toExternalNamespace(out), nil
+}
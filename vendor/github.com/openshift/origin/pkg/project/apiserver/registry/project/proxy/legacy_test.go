@@ -0,0 +1,33 @@
+package proxy
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestToExternalNamespaceList(t *testing.T) {
+	internal := &kapi.NamespaceList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "42"},
+		Items: []kapi.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Status: kapi.NamespaceStatus{Phase: kapi.NamespaceActive}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Status: kapi.NamespaceStatus{Phase: kapi.NamespaceTerminating}},
+		},
+	}
+
+	external := toExternalNamespaceList(internal)
+	if external.ResourceVersion != "42" {
+		t.Errorf("expected ResourceVersion to carry over, got %q", external.ResourceVersion)
+	}
+	if len(external.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(external.Items))
+	}
+	if external.Items[0].Name != "a" || external.Items[0].Status.Phase != corev1.NamespaceActive {
+		t.Errorf("unexpected conversion of item 0: %#v", external.Items[0])
+	}
+	if external.Items[1].Name != "b" || external.Items[1].Status.Phase != corev1.NamespaceTerminating {
+		t.Errorf("unexpected conversion of item 1: %#v", external.Items[1])
+	}
+}
@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
+	projectv1 "github.com/openshift/api/project/v1"
+)
+
+// benchNamespaces builds a synthetic fixture of n authorized namespaces, the
+// shape REST.List works with once projectauth.Lister and toExternalNamespaceList
+// have already produced an external NamespaceList (the part of List this
+// benchmark exercises; the lister/AuthorizationCache scan that produces that
+// NamespaceList lives in pkg/project/auth, which isn't vendored into this
+// tree — see the scoping note on REST.List).
+func benchNamespaces(n int) []corev1.Namespace {
+	namespaces := make([]corev1.Namespace, n)
+	for i := range namespaces {
+		namespaces[i] = corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("project-%05d", i)},
+		}
+	}
+	return namespaces
+}
+
+// BenchmarkListWithoutPagination models the pre-chunk0-4 behavior: every call
+// filters, sorts, and converts the entire authorized namespace set, no matter
+// how many Projects the caller actually wants.
+func BenchmarkListWithoutPagination(b *testing.B) {
+	namespaces := benchNamespaces(10000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		filtered, err := filterList(namespaces, matchNamespace(labels.Everything(), fields.Everything()))
+		if err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+		sort.Slice(filtered, func(i, j int) bool { return filtered[i].Name < filtered[j].Name })
+
+		projects := make([]*projectv1.Project, 0, len(filtered))
+		for j := range filtered {
+			projects = append(projects, convertNamespaceToProject(&filtered[j]))
+		}
+		if len(projects) != len(namespaces) {
+			b.Fatalf("expected %d projects, got %d", len(namespaces), len(projects))
+		}
+	}
+}
+
+// BenchmarkListPaginated models the chunk0-4 path: the same filter and sort,
+// but Limit/Continue mean only one page is ever sliced and converted, so the
+// conversion cost stops scaling with the size of the authorized namespace set.
+// The filter and sort still run over the whole set on every call.
+func BenchmarkListPaginated(b *testing.B) {
+	namespaces := benchNamespaces(10000)
+	const pageSize = 100
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		filtered, err := filterList(namespaces, matchNamespace(labels.Everything(), fields.Everything()))
+		if err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+		sort.Slice(filtered, func(i, j int) bool { return filtered[i].Name < filtered[j].Name })
+
+		end := pageSize
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+		projects := make([]*projectv1.Project, 0, end)
+		for j := 0; j < end; j++ {
+			projects = append(projects, convertNamespaceToProject(&filtered[j]))
+		}
+		if len(projects) != pageSize {
+			b.Fatalf("expected a %d-item page, got %d", pageSize, len(projects))
+		}
+	}
+}
@@ -2,38 +2,44 @@ package proxy
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
 	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/generic"
 	"k8s.io/apiserver/pkg/registry/rest"
 	kstorage "k8s.io/apiserver/pkg/storage"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	kapi "k8s.io/kubernetes/pkg/apis/core"
-	kcoreclient "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/typed/core/internalversion"
 	"k8s.io/kubernetes/pkg/printers"
 	printerstorage "k8s.io/kubernetes/pkg/printers/storage"
-	nsregistry "k8s.io/kubernetes/pkg/registry/core/namespace"
 
 	"github.com/openshift/api/project"
+	projectv1 "github.com/openshift/api/project/v1"
 	"github.com/openshift/origin/pkg/api/apihelpers"
 	authorizationapi "github.com/openshift/origin/pkg/authorization/apis/authorization"
 	"github.com/openshift/origin/pkg/authorization/authorizer/scope"
 	printersinternal "github.com/openshift/origin/pkg/printers/internalversion"
-	projectapi "github.com/openshift/origin/pkg/project/apis/project"
 	projectregistry "github.com/openshift/origin/pkg/project/apiserver/registry/project"
 	projectauth "github.com/openshift/origin/pkg/project/auth"
 	projectcache "github.com/openshift/origin/pkg/project/cache"
-	projectutil "github.com/openshift/origin/pkg/project/util"
 )
 
 type REST struct {
-	// client can modify Kubernetes namespaces
-	client kcoreclient.NamespaceInterface
+	// client can modify Kubernetes namespaces. It talks to the external,
+	// versioned API directly rather than going through an internal clientset.
+	client corev1client.NamespaceInterface
 	// lister can enumerate project lists that enforce policy
 	lister projectauth.Lister
 	// Allows extended behavior during creation, required
@@ -44,63 +50,136 @@ type REST struct {
 	authCache    *projectauth.AuthorizationCache
 	projectCache *projectcache.ProjectCache
 
+	// watchBookmarkInterval is how often Watch emits a watch.Bookmark event
+	// when the caller opts in via AllowWatchBookmarks. Zero means
+	// defaultWatchBookmarkInterval.
+	watchBookmarkInterval time.Duration
+
 	rest.TableConvertor
 }
 
+// SetWatchBookmarkInterval overrides the interval at which Watch emits
+// watch.Bookmark events for callers that opt in via AllowWatchBookmarks.
+func (s *REST) SetWatchBookmarkInterval(interval time.Duration) {
+	s.watchBookmarkInterval = interval
+}
+
+// defaultWatchBookmarkInterval is used when watchBookmarkInterval is unset.
+const defaultWatchBookmarkInterval = time.Minute
+
 var _ rest.Lister = &REST{}
 var _ rest.CreaterUpdater = &REST{}
 var _ rest.GracefulDeleter = &REST{}
 var _ rest.Watcher = &REST{}
 var _ rest.Scoper = &REST{}
 
-// NewREST returns a RESTStorage object that will work against Project resources
-func NewREST(client kcoreclient.NamespaceInterface, lister projectauth.Lister, authCache *projectauth.AuthorizationCache, projectCache *projectcache.ProjectCache) *REST {
+// NewREST returns a RESTStorage object that will work against Project
+// resources, storing them as external corev1 Namespaces. defaultNodeSelector,
+// if non-empty, must be a valid `k=v,k2=v2` label selector and is applied to
+// every Project created without an explicit NodeSelectorAnnotation.
+func NewREST(client corev1client.NamespaceInterface, lister projectauth.Lister, authCache *projectauth.AuthorizationCache, projectCache *projectcache.ProjectCache, defaultNodeSelector string) (*REST, error) {
+	if len(defaultNodeSelector) > 0 {
+		if _, err := labels.Parse(defaultNodeSelector); err != nil {
+			return nil, fmt.Errorf("invalid default node selector %q: %v", defaultNodeSelector, err)
+		}
+	}
+	strategy := projectregistry.NewStrategy(defaultNodeSelector)
 	return &REST{
 		client:         client,
 		lister:         lister,
-		createStrategy: projectregistry.Strategy,
-		updateStrategy: projectregistry.Strategy,
+		createStrategy: strategy,
+		updateStrategy: strategy,
 
 		authCache:    authCache,
 		projectCache: projectCache,
 
 		TableConvertor: printerstorage.TableConvertor{TablePrinter: printers.NewTablePrinter().With(printersinternal.AddHandlers)},
-	}
+	}, nil
 }
 
 // New returns a new Project
 func (s *REST) New() runtime.Object {
-	return &projectapi.Project{}
+	return &projectv1.Project{}
 }
 
 // NewList returns a new ProjectList
 func (*REST) NewList() runtime.Object {
-	return &projectapi.ProjectList{}
+	return &projectv1.ProjectList{}
 }
 
 func (s *REST) NamespaceScoped() bool {
 	return false
 }
 
-// List retrieves a list of Projects that match label.
-
+// List retrieves a list of Projects the user is authorized to see, matching
+// label and field selectors and obeying options.Limit/options.Continue.
+// Projects are ordered by name so Continue tokens are stable across calls.
+//
+// s.lister (projectauth.Lister) still enumerates internal-typed Namespaces:
+// migrating pkg/project/auth's informer and AuthorizationCache wiring to the
+// external API is out of scope here, since this change only touches
+// pkg/project/apiserver and pkg/project/controller. toExternalNamespaceList
+// is the deliberate seam between the two until that migration happens.
 func (s *REST) List(ctx context.Context, options *metainternal.ListOptions) (runtime.Object, error) {
 	user, ok := apirequest.UserFrom(ctx)
 	if !ok {
 		return nil, kerrors.NewForbidden(project.Resource("project"), "", fmt.Errorf("unable to list projects without a user on the context"))
 	}
-	namespaceList, err := s.lister.List(user)
+	rawList, err := s.lister.List(user)
 	if err != nil {
 		return nil, err
 	}
-	m := nsregistry.MatchNamespace(apihelpers.InternalListOptionsToSelectors(options))
-	list, err := filterList(namespaceList, m, nil)
+	internalList, ok := rawList.(*kapi.NamespaceList)
+	if !ok {
+		return nil, fmt.Errorf("project lister returned unexpected type %T", rawList)
+	}
+	namespaceList := toExternalNamespaceList(internalList)
+	label, field := apihelpers.InternalListOptionsToSelectors(options)
+	filtered, err := filterList(namespaceList.Items, matchNamespace(label, field))
 	if err != nil {
 		return nil, err
 	}
-	return projectutil.ConvertNamespaceList(list.(*kapi.NamespaceList)), nil
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Name < filtered[j].Name })
+
+	resourceVersion := namespaceList.ResourceVersion
+	if len(resourceVersion) == 0 {
+		resourceVersion = s.authCache.LastSyncResourceVersion()
+	}
+
+	start := 0
+	if options != nil && len(options.Continue) > 0 {
+		token, err := decodeContinueToken(options.Continue)
+		if err != nil {
+			return nil, kerrors.NewBadRequest(fmt.Sprintf("invalid continue token: %v", err))
+		}
+		if token.ResourceVersion != resourceVersion {
+			return nil, kerrors.NewBadRequest("continue token is out of date, please restart the list")
+		}
+		start = sort.Search(len(filtered), func(i int) bool { return filtered[i].Name > token.LastName })
+	}
+
+	end := len(filtered)
+	var nextContinue string
+	if options != nil && options.Limit > 0 && int64(end-start) > options.Limit {
+		end = start + int(options.Limit)
+		nextContinue = encodeContinueToken(resourceVersion, filtered[end-1].Name)
+	}
+
+	projects := &projectv1.ProjectList{ListMeta: metav1.ListMeta{
+		ResourceVersion: resourceVersion,
+		Continue:        nextContinue,
+	}}
+	for i := start; i < end; i++ {
+		projects.Items = append(projects.Items, *convertNamespaceToProject(&filtered[i]))
+	}
+	return projects, nil
 }
 
+// Watch streams changes to the Projects the user is authorized to see. When
+// options.AllowWatchBookmarks is set, a watch.Bookmark event carrying the
+// AuthorizationCache's latest observed resource version is emitted every
+// watchBookmarkInterval (defaultWatchBookmarkInterval if unset), letting
+// long-lived clients advance their resource version without a full relist.
 func (s *REST) Watch(ctx context.Context, options *metainternal.ListOptions) (watch.Interface, error) {
 	if ctx == nil {
 		return nil, fmt.Errorf("Context is nil")
@@ -121,6 +200,14 @@ func (s *REST) Watch(ctx context.Context, options *metainternal.ListOptions) (wa
 	s.authCache.AddWatcher(watcher)
 
 	go watcher.Watch()
+
+	if options != nil && options.AllowWatchBookmarks {
+		interval := s.watchBookmarkInterval
+		if interval <= 0 {
+			interval = defaultWatchBookmarkInterval
+		}
+		return newBookmarkingWatcher(watcher, s.authCache, interval), nil
+	}
 	return watcher, nil
 }
 
@@ -136,18 +223,25 @@ func (s *REST) Get(ctx context.Context, name string, options *metav1.GetOptions)
 	if err != nil {
 		return nil, err
 	}
-	return projectutil.ConvertNamespace(namespace), nil
+	return convertNamespaceToProject(namespace), nil
 }
 
 var _ = rest.Creater(&REST{})
 
 // Create registers the given Project.
 func (s *REST) Create(ctx context.Context, obj runtime.Object, creationValidation rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
-	projectObj, ok := obj.(*projectapi.Project)
+	projectObj, ok := obj.(*projectv1.Project)
 	if !ok {
 		return nil, fmt.Errorf("not a project: %#v", obj)
 	}
 	rest.FillObjectMetaSystemFields(&projectObj.ObjectMeta)
+	if existing, err := s.client.Get(projectObj.Name, metav1.GetOptions{}); err == nil {
+		if existing.DeletionTimestamp != nil {
+			return nil, kerrors.NewConflict(project.Resource("project"), projectObj.Name, fmt.Errorf("project %q is still terminating and cannot be recreated until its previous instance finishes deleting", projectObj.Name))
+		}
+	} else if !kerrors.IsNotFound(err) {
+		return nil, err
+	}
 	s.createStrategy.PrepareForCreate(ctx, obj)
 	if errs := s.createStrategy.Validate(ctx, obj); len(errs) > 0 {
 		return nil, kerrors.NewInvalid(project.Kind("Project"), projectObj.Name, errs)
@@ -156,11 +250,11 @@ func (s *REST) Create(ctx context.Context, obj runtime.Object, creationValidatio
 		return nil, err
 	}
 
-	namespace, err := s.client.Create(projectutil.ConvertProject(projectObj))
+	namespace, err := s.client.Create(convertProjectToNamespace(projectObj))
 	if err != nil {
 		return nil, err
 	}
-	return projectutil.ConvertNamespace(namespace), nil
+	return convertNamespaceToProject(namespace), nil
 }
 
 var _ = rest.Updater(&REST{})
@@ -176,10 +270,13 @@ func (s *REST) Update(ctx context.Context, name string, objInfo rest.UpdatedObje
 		return nil, false, err
 	}
 
-	projectObj, ok := obj.(*projectapi.Project)
+	projectObj, ok := obj.(*projectv1.Project)
 	if !ok {
 		return nil, false, fmt.Errorf("not a project: %#v", obj)
 	}
+	if _, ok := oldObj.(*projectv1.Project); !ok {
+		return nil, false, fmt.Errorf("not a project: %#v", oldObj)
+	}
 
 	s.updateStrategy.PrepareForUpdate(ctx, obj, oldObj)
 	if errs := s.updateStrategy.ValidateUpdate(ctx, obj, oldObj); len(errs) > 0 {
@@ -189,52 +286,173 @@ func (s *REST) Update(ctx context.Context, name string, objInfo rest.UpdatedObje
 		return nil, false, err
 	}
 
-	namespace, err := s.client.Update(projectutil.ConvertProject(projectObj))
+	namespace, err := s.client.Update(convertProjectToNamespace(projectObj))
 	if err != nil {
 		return nil, false, err
 	}
 
-	return projectutil.ConvertNamespace(namespace), false, nil
+	return convertNamespaceToProject(namespace), false, nil
 }
 
 var _ = rest.GracefulDeleter(&REST{})
 
-// Delete deletes a Project specified by its name
+// Delete deletes a Project specified by its name. Deletion is graceful: it
+// honors options.GracePeriodSeconds and options.PropagationPolicy, and it
+// ensures OriginFinalizer is present on the underlying Namespace so the
+// project-scoped resources (BuildConfigs, ImageStreams, RoleBindings, etc.)
+// are drained by the finalizer controller before the Namespace, and
+// therefore the Project, is actually removed. The project is considered
+// deleted (the bool return is true) only once the Namespace is gone, not
+// merely once it starts terminating.
 func (s *REST) Delete(ctx context.Context, name string, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
-	return &metav1.Status{Status: metav1.StatusSuccess}, false, s.client.Delete(name, nil)
+	namespace, err := s.client.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+	if !HasFinalizer(namespace.Spec.Finalizers, OriginFinalizer) {
+		namespace.Spec.Finalizers = append(namespace.Spec.Finalizers, OriginFinalizer)
+		if namespace, err = s.client.Update(namespace); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := s.client.Delete(name, options); err != nil {
+		return nil, false, err
+	}
+
+	namespace, err = s.client.Get(name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return &metav1.Status{Status: metav1.StatusSuccess}, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return convertNamespaceToProject(namespace), false, nil
+}
+
+// OriginFinalizer is added to a Namespace's Spec.Finalizers when its Project
+// is deleted. The finalizer controller in
+// pkg/project/controller/finalizer removes it once every project-scoped
+// OpenShift resource in the namespace has been drained, allowing the
+// Namespace (and the Project it backs) to actually be removed.
+const OriginFinalizer corev1.FinalizerName = "openshift.io/origin"
+
+// HasFinalizer reports whether finalizer is present in finalizers. It is
+// exported so the finalizer controller that removes OriginFinalizer can
+// share this check instead of reimplementing it.
+func HasFinalizer(finalizers []corev1.FinalizerName, finalizer corev1.FinalizerName) bool {
+	for _, f := range finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
 }
 
-// decoratorFunc can mutate the provided object prior to being returned.
-type decoratorFunc func(obj runtime.Object) error
+// convertNamespaceToProject maps a corev1.Namespace directly onto a
+// projectv1.Project. The two resources share the same ObjectMeta, finalizer
+// list, and phase, so this is a field-for-field copy rather than a generic
+// conversion.
+func convertNamespaceToProject(namespace *corev1.Namespace) *projectv1.Project {
+	return &projectv1.Project{
+		ObjectMeta: namespace.ObjectMeta,
+		Spec: projectv1.ProjectSpec{
+			Finalizers: namespace.Spec.Finalizers,
+		},
+		Status: projectv1.ProjectStatus{
+			Phase: namespace.Status.Phase,
+		},
+	}
+}
+
+// convertProjectToNamespace is the inverse of convertNamespaceToProject.
+func convertProjectToNamespace(project *projectv1.Project) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: project.ObjectMeta,
+		Spec: corev1.NamespaceSpec{
+			Finalizers: project.Spec.Finalizers,
+		},
+		Status: corev1.NamespaceStatus{
+			Phase: project.Status.Phase,
+		},
+	}
+}
+
+// matchNamespace returns a SelectionPredicate that matches a corev1.Namespace
+// against the given label and field selectors. It replaces the internal
+// k8s.io/kubernetes/pkg/registry/core/namespace matcher now that this storage
+// talks to external Namespace objects.
+func matchNamespace(label labels.Selector, field fields.Selector) kstorage.SelectionPredicate {
+	return kstorage.SelectionPredicate{
+		Label:    label,
+		Field:    field,
+		GetAttrs: namespaceAttributes,
+	}
+}
+
+// projectAnnotationFields are the standard project annotations exposed as
+// selectable fields, in addition to metadata.name and status.phase.
+var projectAnnotationFields = []string{
+	projectv1.ProjectDisplayName,
+	projectv1.ProjectDescription,
+	projectv1.ProjectRequester,
+}
 
-// filterList filters any list object that conforms to the api conventions,
-// provided that 'm' works with the concrete type of list. d is an optional
-// decorator for the returned functions. Only matching items are decorated.
-func filterList(list runtime.Object, m kstorage.SelectionPredicate, d decoratorFunc) (filtered runtime.Object, err error) {
-	// TODO: push a matcher down into tools.etcdHelper to avoid all this
-	// nonsense. This is a lot of unnecessary copies.
-	items, err := meta.ExtractList(list)
+func namespaceAttributes(obj runtime.Object) (labels.Set, fields.Set, error) {
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return nil, nil, fmt.Errorf("not a namespace: %#v", obj)
+	}
+	objectMetaFieldsSet := generic.ObjectMetaFieldsSet(&namespace.ObjectMeta, false)
+	specificFieldsSet := fields.Set{
+		"status.phase": string(namespace.Status.Phase),
+	}
+	for _, annotation := range projectAnnotationFields {
+		if value, ok := namespace.Annotations[annotation]; ok {
+			specificFieldsSet[annotation] = value
+		}
+	}
+	return namespace.Labels, generic.MergeFieldsSets(objectMetaFieldsSet, specificFieldsSet), nil
+}
+
+// continueToken is the decoded form of a List Continue token: the resource
+// version the listing was taken at and the name of the last item already
+// returned. Encoding it as opaque base64 JSON keeps the wire format free to
+// change without breaking clients, which only ever round-trip it verbatim.
+type continueToken struct {
+	ResourceVersion string `json:"rv"`
+	LastName        string `json:"lastName"`
+}
+
+func encodeContinueToken(resourceVersion, lastName string) string {
+	raw, _ := json.Marshal(continueToken{ResourceVersion: resourceVersion, LastName: lastName})
+	return base64.URLEncoding.EncodeToString(raw)
+}
+
+func decodeContinueToken(encoded string) (continueToken, error) {
+	raw, err := base64.URLEncoding.DecodeString(encoded)
 	if err != nil {
-		return nil, err
+		return continueToken{}, err
 	}
-	var filteredItems []runtime.Object
-	for _, obj := range items {
-		match, err := m.Matches(obj)
+	var token continueToken
+	if err := json.Unmarshal(raw, &token); err != nil {
+		return continueToken{}, err
+	}
+	return token, nil
+}
+
+// filterList applies m to each namespace in list, returning only the ones
+// that match.
+func filterList(list []corev1.Namespace, m kstorage.SelectionPredicate) ([]corev1.Namespace, error) {
+	filtered := make([]corev1.Namespace, 0, len(list))
+	for i := range list {
+		match, err := m.Matches(&list[i])
 		if err != nil {
 			return nil, err
 		}
 		if match {
-			if d != nil {
-				if err := d(obj); err != nil {
-					return nil, err
-				}
-			}
-			filteredItems = append(filteredItems, obj)
+			filtered = append(filtered, list[i])
 		}
 	}
-	err = meta.SetList(list, filteredItems)
-	if err != nil {
-		return nil, err
-	}
-	return list, nil
+	return filtered, nil
 }
@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
+	projectv1 "github.com/openshift/api/project/v1"
+)
+
+func TestConvertNamespaceProjectRoundTrip(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-project", Annotations: map[string]string{"a": "b"}},
+		Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{OriginFinalizer}},
+		Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+	}
+
+	projectObj := convertNamespaceToProject(namespace)
+	if projectObj.Name != namespace.Name || projectObj.Status.Phase != corev1.NamespaceActive {
+		t.Fatalf("unexpected conversion: %#v", projectObj)
+	}
+	if len(projectObj.Spec.Finalizers) != 1 || projectObj.Spec.Finalizers[0] != OriginFinalizer {
+		t.Fatalf("expected finalizers to carry over, got %v", projectObj.Spec.Finalizers)
+	}
+
+	roundTripped := convertProjectToNamespace(projectObj)
+	if roundTripped.Name != namespace.Name || roundTripped.Status.Phase != namespace.Status.Phase {
+		t.Fatalf("round trip mismatch: %#v vs %#v", roundTripped, namespace)
+	}
+}
+
+func TestFilterListMatchesLabelAndField(t *testing.T) {
+	namespaces := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"team": "x"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Labels: map[string]string{"team": "y"}}},
+	}
+
+	label, err := labels.Parse("team=x")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filtered, err := filterList(namespaces, matchNamespace(label, fields.Everything()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Name != "a" {
+		t.Fatalf("expected only namespace %q to match, got %v", "a", filtered)
+	}
+}
+
+func TestFilterListMatchesAnnotationField(t *testing.T) {
+	namespaces := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Annotations: map[string]string{projectv1.ProjectDisplayName: "Alpha"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Annotations: map[string]string{projectv1.ProjectDisplayName: "Beta"}}},
+	}
+
+	field, err := fields.ParseSelector(projectv1.ProjectDisplayName + "=Alpha")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filtered, err := filterList(namespaces, matchNamespace(labels.Everything(), field))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Name != "a" {
+		t.Fatalf("expected only namespace %q to match, got %v", "a", filtered)
+	}
+}
+
+func TestHasFinalizer(t *testing.T) {
+	finalizers := []corev1.FinalizerName{OriginFinalizer}
+	if !HasFinalizer(finalizers, OriginFinalizer) {
+		t.Errorf("expected HasFinalizer to find %q", OriginFinalizer)
+	}
+	if HasFinalizer(finalizers, corev1.FinalizerName("other")) {
+		t.Errorf("expected HasFinalizer not to find an absent finalizer")
+	}
+}
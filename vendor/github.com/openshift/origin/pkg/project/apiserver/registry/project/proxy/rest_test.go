@@ -0,0 +1,177 @@
+package proxy
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	projectv1 "github.com/openshift/api/project/v1"
+)
+
+// fakeNamespaceClient is an in-memory corev1client.NamespaceInterface used to
+// integration-test proxy.REST's Create/Get/Update/Delete against a fake
+// external clientset. List/Watch aren't exercised here: REST.List/Watch go
+// through projectauth.Lister and AuthorizationCache, which this change
+// doesn't touch (see the scoping note on REST.List).
+type fakeNamespaceClient struct {
+	corev1client.NamespaceInterface
+	namespaces map[string]*corev1.Namespace
+	// onDeleteHook, if set, runs at the end of Delete. Tests use it to
+	// simulate another actor (e.g. the finalizer controller) racing the
+	// namespace to actual removal.
+	onDeleteHook func()
+}
+
+func newFakeNamespaceClient() *fakeNamespaceClient {
+	return &fakeNamespaceClient{namespaces: map[string]*corev1.Namespace{}}
+}
+
+func (f *fakeNamespaceClient) Create(namespace *corev1.Namespace) (*corev1.Namespace, error) {
+	if _, exists := f.namespaces[namespace.Name]; exists {
+		return nil, kerrors.NewAlreadyExists(corev1.Resource("namespaces"), namespace.Name)
+	}
+	stored := namespace.DeepCopy()
+	f.namespaces[namespace.Name] = stored
+	return stored.DeepCopy(), nil
+}
+
+func (f *fakeNamespaceClient) Update(namespace *corev1.Namespace) (*corev1.Namespace, error) {
+	if _, exists := f.namespaces[namespace.Name]; !exists {
+		return nil, kerrors.NewNotFound(corev1.Resource("namespaces"), namespace.Name)
+	}
+	stored := namespace.DeepCopy()
+	f.namespaces[namespace.Name] = stored
+	return stored.DeepCopy(), nil
+}
+
+func (f *fakeNamespaceClient) Get(name string, options metav1.GetOptions) (*corev1.Namespace, error) {
+	namespace, exists := f.namespaces[name]
+	if !exists {
+		return nil, kerrors.NewNotFound(corev1.Resource("namespaces"), name)
+	}
+	return namespace.DeepCopy(), nil
+}
+
+// Delete mimics a real apiserver: a namespace with finalizers set is kept
+// around (marked terminating) rather than actually removed, just like
+// REST.Delete expects when it re-Gets to decide whether the Project is gone.
+func (f *fakeNamespaceClient) Delete(name string, options *metav1.DeleteOptions) error {
+	namespace, exists := f.namespaces[name]
+	if !exists {
+		return kerrors.NewNotFound(corev1.Resource("namespaces"), name)
+	}
+	if len(namespace.Spec.Finalizers) > 0 {
+		now := metav1.Now()
+		namespace.DeletionTimestamp = &now
+		namespace.Status.Phase = corev1.NamespaceTerminating
+	} else {
+		delete(f.namespaces, name)
+	}
+	if f.onDeleteHook != nil {
+		f.onDeleteHook()
+	}
+	return nil
+}
+
+func noopValidate(runtime.Object) error                       { return nil }
+func noopValidateUpdate(runtime.Object, runtime.Object) error { return nil }
+
+func newTestREST(t *testing.T, client *fakeNamespaceClient) *REST {
+	t.Helper()
+	s, err := NewREST(client, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("unexpected error constructing REST: %v", err)
+	}
+	return s
+}
+
+func TestRESTCreateGetUpdateDeleteRoundTrip(t *testing.T) {
+	client := newFakeNamespaceClient()
+	s := newTestREST(t, client)
+	ctx := apirequest.NewDefaultContext()
+
+	created, err := s.Create(ctx, &projectv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "my-project"}}, noopValidate, false)
+	if err != nil {
+		t.Fatalf("Create: unexpected error: %v", err)
+	}
+	createdProject := created.(*projectv1.Project)
+	if createdProject.Name != "my-project" {
+		t.Fatalf("Create: unexpected project: %#v", createdProject)
+	}
+
+	got, err := s.Get(ctx, "my-project", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get: unexpected error: %v", err)
+	}
+	if got.(*projectv1.Project).Name != "my-project" {
+		t.Fatalf("Get: unexpected project: %#v", got)
+	}
+
+	toUpdate := got.(*projectv1.Project).DeepCopy()
+	toUpdate.Annotations = map[string]string{"updated": "true"}
+	updated, _, err := s.Update(ctx, "my-project", rest.DefaultUpdatedObjectInfo(toUpdate), noopValidate, noopValidateUpdate)
+	if err != nil {
+		t.Fatalf("Update: unexpected error: %v", err)
+	}
+	if updated.(*projectv1.Project).Annotations["updated"] != "true" {
+		t.Fatalf("Update: annotation didn't round-trip: %#v", updated)
+	}
+
+	// Delete only marks the namespace terminating with OriginFinalizer set;
+	// actual removal waits on the finalizer controller (see
+	// TestRESTDeleteCompletesWhenNamespaceAlreadyGone).
+	obj, done, err := s.Delete(ctx, "my-project", &metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("Delete: unexpected error: %v", err)
+	}
+	if done {
+		t.Fatalf("Delete: expected completion to wait on the finalizer controller")
+	}
+	if !HasFinalizer(obj.(*projectv1.Project).Spec.Finalizers, OriginFinalizer) {
+		t.Fatalf("Delete: expected OriginFinalizer to be set, got %v", obj.(*projectv1.Project).Spec.Finalizers)
+	}
+}
+
+// TestRESTDeleteCompletesWhenNamespaceAlreadyGone covers the fast path: if
+// the Namespace is already gone by the time Delete re-Gets it (for example,
+// another client raced the deletion to completion), REST.Delete reports the
+// Project as fully deleted rather than erroring.
+func TestRESTDeleteCompletesWhenNamespaceAlreadyGone(t *testing.T) {
+	client := newFakeNamespaceClient()
+	s := newTestREST(t, client)
+	ctx := apirequest.NewDefaultContext()
+
+	client.namespaces["my-project"] = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-project"}}
+	client.onDeleteHook = func() { delete(client.namespaces, "my-project") }
+
+	obj, done, err := s.Delete(ctx, "my-project", &metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !done {
+		t.Fatalf("expected Delete to report completion once the namespace is gone, got %#v", obj)
+	}
+}
+
+func TestRESTCreateRejectsRecreateWhileTerminating(t *testing.T) {
+	client := newFakeNamespaceClient()
+	s := newTestREST(t, client)
+	ctx := apirequest.NewDefaultContext()
+
+	now := metav1.Now()
+	client.namespaces["my-project"] = &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-project", DeletionTimestamp: &now},
+	}
+
+	_, err := s.Create(ctx, &projectv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "my-project"}}, noopValidate, false)
+	if !kerrors.IsConflict(err) {
+		t.Fatalf("expected a conflict recreating a terminating project, got %v", err)
+	}
+}
+
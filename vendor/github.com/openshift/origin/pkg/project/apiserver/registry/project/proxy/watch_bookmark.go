@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	projectv1 "github.com/openshift/api/project/v1"
+)
+
+// resourceVersioner is satisfied by projectauth.AuthorizationCache. It is
+// defined locally so bookmarkingWatcher doesn't need to import the auth
+// package just for this one method.
+type resourceVersioner interface {
+	LastSyncResourceVersion() string
+}
+
+// bookmarkingWatcher wraps inner, periodically injecting a watch.Bookmark
+// event carrying rv's latest observed resource version. Events from inner
+// are passed through unchanged.
+type bookmarkingWatcher struct {
+	inner watch.Interface
+	out   chan watch.Event
+	done  chan struct{}
+}
+
+func newBookmarkingWatcher(inner watch.Interface, rv resourceVersioner, interval time.Duration) *bookmarkingWatcher {
+	w := &bookmarkingWatcher{
+		inner: inner,
+		out:   make(chan watch.Event),
+		done:  make(chan struct{}),
+	}
+	go w.run(rv, interval)
+	return w
+}
+
+func (w *bookmarkingWatcher) run(rv resourceVersioner, interval time.Duration) {
+	defer close(w.out)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-w.inner.ResultChan():
+			if !ok {
+				return
+			}
+			select {
+			case w.out <- event:
+			case <-w.done:
+				return
+			}
+		case <-ticker.C:
+			bookmark := watch.Event{
+				Type: watch.Bookmark,
+				Object: &projectv1.Project{
+					ObjectMeta: metav1.ObjectMeta{ResourceVersion: rv.LastSyncResourceVersion()},
+				},
+			}
+			select {
+			case w.out <- bookmark:
+			case <-w.done:
+				return
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *bookmarkingWatcher) Stop() {
+	close(w.done)
+	w.inner.Stop()
+}
+
+func (w *bookmarkingWatcher) ResultChan() <-chan watch.Event {
+	return w.out
+}
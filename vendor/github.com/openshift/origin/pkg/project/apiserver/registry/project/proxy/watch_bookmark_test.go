@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	projectv1 "github.com/openshift/api/project/v1"
+)
+
+type fakeResourceVersioner struct {
+	rv string
+}
+
+func (f *fakeResourceVersioner) LastSyncResourceVersion() string {
+	return f.rv
+}
+
+type fakeWatcher struct {
+	events chan watch.Event
+}
+
+func newFakeWatcher() *fakeWatcher {
+	return &fakeWatcher{events: make(chan watch.Event)}
+}
+
+func (f *fakeWatcher) Stop()                          { close(f.events) }
+func (f *fakeWatcher) ResultChan() <-chan watch.Event { return f.events }
+
+func TestBookmarkingWatcherPassesThroughEvents(t *testing.T) {
+	inner := newFakeWatcher()
+	w := newBookmarkingWatcher(inner, &fakeResourceVersioner{rv: "1"}, time.Hour)
+	defer w.Stop()
+
+	want := watch.Event{Type: watch.Added, Object: &projectv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "proj"}}}
+	inner.events <- want
+
+	select {
+	case got := <-w.ResultChan():
+		if got.Type != want.Type {
+			t.Errorf("expected event type %v, got %v", want.Type, got.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for passthrough event")
+	}
+}
+
+func TestBookmarkingWatcherEmitsBookmarks(t *testing.T) {
+	inner := newFakeWatcher()
+	w := newBookmarkingWatcher(inner, &fakeResourceVersioner{rv: "42"}, 10*time.Millisecond)
+	defer w.Stop()
+
+	select {
+	case event := <-w.ResultChan():
+		if event.Type != watch.Bookmark {
+			t.Fatalf("expected a bookmark event, got %v", event.Type)
+		}
+		projectObj, ok := event.Object.(*projectv1.Project)
+		if !ok || projectObj.ResourceVersion != "42" {
+			t.Errorf("expected bookmark to carry resource version 42, got %#v", event.Object)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for bookmark event")
+	}
+}
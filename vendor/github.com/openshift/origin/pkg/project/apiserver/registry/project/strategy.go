@@ -0,0 +1,140 @@
+package project
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/storage/names"
+
+	projectv1 "github.com/openshift/api/project/v1"
+	"github.com/openshift/origin/pkg/api/legacyscheme"
+)
+
+// NodeSelectorAnnotation holds the project's default node selector in
+// `k=v,k2=v2` form. The scheduling admission plugin merges it with each
+// incoming pod's own node selector, and rejects a pod whose selector sets a
+// different value for a key the project's selector also sets.
+const NodeSelectorAnnotation = "openshift.io/node-selector"
+
+// clusterAdminUsername is the identity OpenShift's bootstrap policy binds the
+// cluster-admin role to. It is the only identity allowed to set an explicit
+// empty NodeSelectorAnnotation to opt a project out of the cluster default.
+const clusterAdminUsername = "system:admin"
+
+// projectStrategy implements the create/update extension points used by
+// proxy.REST. It owns validation and defaulting of the project's
+// NodeSelectorAnnotation so that logic lives alongside the rest of the
+// object's validation rather than bolted onto the REST storage layer.
+type projectStrategy struct {
+	runtime.ObjectTyper
+	names.NameGenerator
+
+	// defaultNodeSelector is applied to a Project's NodeSelectorAnnotation at
+	// create time when the caller did not supply one.
+	defaultNodeSelector string
+}
+
+// NewStrategy returns a projectStrategy that applies defaultNodeSelector, if
+// non-empty, to every Project created without an explicit
+// NodeSelectorAnnotation. defaultNodeSelector must already be a valid
+// `k=v,k2=v2` label selector; callers are expected to have validated it once
+// at startup.
+func NewStrategy(defaultNodeSelector string) projectStrategy {
+	return projectStrategy{
+		ObjectTyper:         legacyscheme.Scheme,
+		NameGenerator:       names.SimpleNameGenerator,
+		defaultNodeSelector: defaultNodeSelector,
+	}
+}
+
+func (projectStrategy) NamespaceScoped() bool {
+	return false
+}
+
+// PrepareForCreate fills in obj's NodeSelectorAnnotation when the caller did
+// not supply one.
+func (s projectStrategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+	projectObj := obj.(*projectv1.Project)
+	if _, hasSelector := projectObj.Annotations[NodeSelectorAnnotation]; hasSelector || len(s.defaultNodeSelector) == 0 {
+		return
+	}
+	if projectObj.Annotations == nil {
+		projectObj.Annotations = map[string]string{}
+	}
+	projectObj.Annotations[NodeSelectorAnnotation] = s.defaultNodeSelector
+}
+
+// Validate validates a new Project. A caller may supply an explicit empty
+// selector to opt a project out of the cluster default, but only the cluster
+// admin identity is allowed to do so; any other explicit value must parse as
+// a label selector.
+func (projectStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	projectObj := obj.(*projectv1.Project)
+	selector, hasSelector := projectObj.Annotations[NodeSelectorAnnotation]
+	if !hasSelector {
+		return nil
+	}
+	if len(selector) == 0 {
+		user, ok := apirequest.UserFrom(ctx)
+		if !ok || user.GetName() != clusterAdminUsername {
+			return field.ErrorList{field.Forbidden(field.NewPath("metadata", "annotations", NodeSelectorAnnotation), fmt.Sprintf("only %s may set an empty %s", clusterAdminUsername, NodeSelectorAnnotation))}
+		}
+		return nil
+	}
+	if _, err := labels.Parse(selector); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("metadata", "annotations", NodeSelectorAnnotation), selector, err.Error())}
+	}
+	return nil
+}
+
+func (projectStrategy) Canonicalize(obj runtime.Object) {}
+
+func (projectStrategy) AllowCreateOnUpdate() bool {
+	return false
+}
+
+func (projectStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {}
+
+// ValidateUpdate rejects a Project update that would widen its
+// NodeSelectorAnnotation: every requirement the project currently enforces
+// must still be present in the proposed selector, since pods already
+// scheduled under the old selector may be relying on it.
+func (projectStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
+	projectObj := obj.(*projectv1.Project)
+	oldProjectObj := old.(*projectv1.Project)
+
+	oldSelector := oldProjectObj.Annotations[NodeSelectorAnnotation]
+	newSelector, changed := projectObj.Annotations[NodeSelectorAnnotation]
+	if oldSelector == newSelector {
+		return nil
+	}
+	if !changed || len(newSelector) == 0 {
+		return field.ErrorList{field.Forbidden(field.NewPath("metadata", "annotations", NodeSelectorAnnotation), "node selector cannot be removed once set")}
+	}
+	oldReqs, err := labels.ParseToRequirements(oldSelector)
+	if err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("metadata", "annotations", NodeSelectorAnnotation), oldSelector, err.Error())}
+	}
+	newSet, err := labels.ConvertSelectorToLabelsMap(newSelector)
+	if err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("metadata", "annotations", NodeSelectorAnnotation), newSelector, err.Error())}
+	}
+	for _, req := range oldReqs {
+		if !req.Matches(newSet) {
+			return field.ErrorList{field.Forbidden(field.NewPath("metadata", "annotations", NodeSelectorAnnotation), "update would widen the project's node selector after pods may already exist")}
+		}
+	}
+	return nil
+}
+
+func (projectStrategy) AllowUnconditionalUpdate() bool {
+	return false
+}
+
+var _ rest.RESTCreateStrategy = projectStrategy{}
+var _ rest.RESTUpdateStrategy = projectStrategy{}
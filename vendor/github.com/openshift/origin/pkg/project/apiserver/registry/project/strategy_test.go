@@ -0,0 +1,92 @@
+package project
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	projectv1 "github.com/openshift/api/project/v1"
+)
+
+func withUser(name string) context.Context {
+	return apirequest.WithUser(context.Background(), &user.DefaultInfo{Name: name})
+}
+
+func TestPrepareForCreateDefaultsSelector(t *testing.T) {
+	strategy := NewStrategy("region=east")
+
+	withoutAnnotation := &projectv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "proj"}}
+	strategy.PrepareForCreate(withUser("alice"), withoutAnnotation)
+	if got := withoutAnnotation.Annotations[NodeSelectorAnnotation]; got != "region=east" {
+		t.Errorf("expected default selector to be applied, got %q", got)
+	}
+
+	explicit := &projectv1.Project{ObjectMeta: metav1.ObjectMeta{
+		Name:        "proj",
+		Annotations: map[string]string{NodeSelectorAnnotation: "region=west"},
+	}}
+	strategy.PrepareForCreate(withUser("alice"), explicit)
+	if got := explicit.Annotations[NodeSelectorAnnotation]; got != "region=west" {
+		t.Errorf("expected explicit selector to be left untouched, got %q", got)
+	}
+}
+
+func TestValidateEmptySelector(t *testing.T) {
+	strategy := NewStrategy("")
+	projectObj := &projectv1.Project{ObjectMeta: metav1.ObjectMeta{
+		Name:        "proj",
+		Annotations: map[string]string{NodeSelectorAnnotation: ""},
+	}}
+
+	if errs := strategy.Validate(withUser(clusterAdminUsername), projectObj); len(errs) != 0 {
+		t.Errorf("expected %s to be allowed to set an empty selector, got %v", clusterAdminUsername, errs)
+	}
+	if errs := strategy.Validate(withUser("alice"), projectObj); len(errs) == 0 {
+		t.Errorf("expected a non-admin setting an empty selector to be rejected")
+	}
+}
+
+func TestValidateInvalidSelector(t *testing.T) {
+	strategy := NewStrategy("")
+	projectObj := &projectv1.Project{ObjectMeta: metav1.ObjectMeta{
+		Name:        "proj",
+		Annotations: map[string]string{NodeSelectorAnnotation: "not a selector!!"},
+	}}
+	if errs := strategy.Validate(withUser("alice"), projectObj); len(errs) == 0 {
+		t.Errorf("expected an invalid selector to be rejected")
+	}
+}
+
+func TestValidateUpdateRejectsWidening(t *testing.T) {
+	strategy := NewStrategy("")
+	old := &projectv1.Project{ObjectMeta: metav1.ObjectMeta{
+		Name:        "proj",
+		Annotations: map[string]string{NodeSelectorAnnotation: "region=east,env=prod"},
+	}}
+
+	widened := old.DeepCopy()
+	widened.Annotations[NodeSelectorAnnotation] = "region=east"
+	if errs := strategy.ValidateUpdate(withUser("alice"), widened, old); len(errs) == 0 {
+		t.Errorf("expected widening the node selector to be rejected")
+	}
+
+	removed := old.DeepCopy()
+	delete(removed.Annotations, NodeSelectorAnnotation)
+	if errs := strategy.ValidateUpdate(withUser("alice"), removed, old); len(errs) == 0 {
+		t.Errorf("expected removing the node selector to be rejected")
+	}
+
+	narrowed := old.DeepCopy()
+	narrowed.Annotations[NodeSelectorAnnotation] = "region=east,env=prod,rack=1"
+	if errs := strategy.ValidateUpdate(withUser("alice"), narrowed, old); len(errs) != 0 {
+		t.Errorf("expected narrowing the node selector to be allowed, got %v", errs)
+	}
+
+	unchanged := old.DeepCopy()
+	if errs := strategy.ValidateUpdate(withUser("alice"), unchanged, old); len(errs) != 0 {
+		t.Errorf("expected an unchanged selector to be allowed, got %v", errs)
+	}
+}
@@ -0,0 +1,84 @@
+package finalizer
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+)
+
+// buildConfigDrainer reports whether any BuildConfig remains in a namespace.
+type buildConfigDrainer struct {
+	client buildv1client.BuildV1Interface
+}
+
+// NewBuildConfigDrainer returns a ResourceDrainer backed by client.
+func NewBuildConfigDrainer(client buildv1client.BuildV1Interface) ResourceDrainer {
+	return &buildConfigDrainer{client: client}
+}
+
+func (d *buildConfigDrainer) Name() string { return "buildconfigs" }
+
+func (d *buildConfigDrainer) Drained(namespace string) (bool, error) {
+	list, err := d.client.BuildConfigs(namespace).List(metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return false, err
+	}
+	return len(list.Items) == 0, nil
+}
+
+// imageStreamDrainer reports whether any ImageStream remains in a namespace.
+type imageStreamDrainer struct {
+	client imagev1client.ImageV1Interface
+}
+
+// NewImageStreamDrainer returns a ResourceDrainer backed by client.
+func NewImageStreamDrainer(client imagev1client.ImageV1Interface) ResourceDrainer {
+	return &imageStreamDrainer{client: client}
+}
+
+func (d *imageStreamDrainer) Name() string { return "imagestreams" }
+
+func (d *imageStreamDrainer) Drained(namespace string) (bool, error) {
+	list, err := d.client.ImageStreams(namespace).List(metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return false, err
+	}
+	return len(list.Items) == 0, nil
+}
+
+// defaultRoleBindingNames are created automatically in every namespace by
+// OpenShift's bootstrap controllers and are never project-scoped user
+// resources, so they must not block namespace finalization.
+var defaultRoleBindingNames = map[string]bool{
+	"system:deployers":      true,
+	"system:image-builders": true,
+	"system:image-pullers":  true,
+}
+
+// roleBindingDrainer reports whether any non-default RoleBinding remains in a
+// namespace.
+type roleBindingDrainer struct {
+	client rbacv1client.RbacV1Interface
+}
+
+// NewRoleBindingDrainer returns a ResourceDrainer backed by client.
+func NewRoleBindingDrainer(client rbacv1client.RbacV1Interface) ResourceDrainer {
+	return &roleBindingDrainer{client: client}
+}
+
+func (d *roleBindingDrainer) Name() string { return "rolebindings" }
+
+func (d *roleBindingDrainer) Drained(namespace string) (bool, error) {
+	list, err := d.client.RoleBindings(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	for _, binding := range list.Items {
+		if !defaultRoleBindingNames[binding.Name] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,142 @@
+package finalizer
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+)
+
+// fakeRoleBindingClient overrides only List; embedding the real interface as
+// a nil field satisfies the rest of it without needing to implement every
+// method client-go generates.
+type fakeRoleBindingClient struct {
+	rbacv1client.RoleBindingInterface
+	list *rbacv1.RoleBindingList
+}
+
+func (f *fakeRoleBindingClient) List(opts metav1.ListOptions) (*rbacv1.RoleBindingList, error) {
+	return f.list, nil
+}
+
+type fakeRbacClient struct {
+	rbacv1client.RbacV1Interface
+	roleBindings rbacv1client.RoleBindingInterface
+}
+
+func (f *fakeRbacClient) RoleBindings(namespace string) rbacv1client.RoleBindingInterface {
+	return f.roleBindings
+}
+
+func TestRoleBindingDrainerIgnoresDefaults(t *testing.T) {
+	client := &fakeRbacClient{roleBindings: &fakeRoleBindingClient{list: &rbacv1.RoleBindingList{
+		Items: []rbacv1.RoleBinding{
+			{ObjectMeta: metav1.ObjectMeta{Name: "system:deployers"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "system:image-builders"}},
+		},
+	}}}
+	drainer := NewRoleBindingDrainer(client)
+
+	drained, err := drainer.Drained("ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !drained {
+		t.Errorf("expected namespace with only default role bindings to be drained")
+	}
+}
+
+func TestRoleBindingDrainerBlocksOnUserBindings(t *testing.T) {
+	client := &fakeRbacClient{roleBindings: &fakeRoleBindingClient{list: &rbacv1.RoleBindingList{
+		Items: []rbacv1.RoleBinding{
+			{ObjectMeta: metav1.ObjectMeta{Name: "system:deployers"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "alice-admin"}},
+		},
+	}}}
+	drainer := NewRoleBindingDrainer(client)
+
+	drained, err := drainer.Drained("ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if drained {
+		t.Errorf("expected a non-default role binding to block draining")
+	}
+}
+
+type fakeBuildConfigClient struct {
+	buildv1client.BuildConfigInterface
+	list *buildv1.BuildConfigList
+}
+
+func (f *fakeBuildConfigClient) List(opts metav1.ListOptions) (*buildv1.BuildConfigList, error) {
+	return f.list, nil
+}
+
+type fakeBuildClient struct {
+	buildv1client.BuildV1Interface
+	buildConfigs buildv1client.BuildConfigInterface
+}
+
+func (f *fakeBuildClient) BuildConfigs(namespace string) buildv1client.BuildConfigInterface {
+	return f.buildConfigs
+}
+
+func TestBuildConfigDrainer(t *testing.T) {
+	client := &fakeBuildClient{buildConfigs: &fakeBuildConfigClient{list: &buildv1.BuildConfigList{}}}
+	drainer := NewBuildConfigDrainer(client)
+
+	drained, err := drainer.Drained("ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !drained {
+		t.Errorf("expected an empty namespace to be drained")
+	}
+
+	client.buildConfigs = &fakeBuildConfigClient{list: &buildv1.BuildConfigList{Items: []buildv1.BuildConfig{{}}}}
+	drained, err = drainer.Drained("ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if drained {
+		t.Errorf("expected a namespace with a BuildConfig not to be drained")
+	}
+}
+
+type fakeImageStreamClient struct {
+	imagev1client.ImageStreamInterface
+	list *imagev1.ImageStreamList
+}
+
+func (f *fakeImageStreamClient) List(opts metav1.ListOptions) (*imagev1.ImageStreamList, error) {
+	return f.list, nil
+}
+
+type fakeImageClient struct {
+	imagev1client.ImageV1Interface
+	imageStreams imagev1client.ImageStreamInterface
+}
+
+func (f *fakeImageClient) ImageStreams(namespace string) imagev1client.ImageStreamInterface {
+	return f.imageStreams
+}
+
+func TestImageStreamDrainer(t *testing.T) {
+	client := &fakeImageClient{imageStreams: &fakeImageStreamClient{list: &imagev1.ImageStreamList{Items: []imagev1.ImageStream{{}}}}}
+	drainer := NewImageStreamDrainer(client)
+
+	drained, err := drainer.Drained("ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if drained {
+		t.Errorf("expected a namespace with an ImageStream not to be drained")
+	}
+}
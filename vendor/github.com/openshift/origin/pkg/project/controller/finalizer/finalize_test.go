@@ -0,0 +1,100 @@
+package finalizer
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/origin/pkg/project/apiserver/registry/project/proxy"
+)
+
+// fakeNamespaceClient is an in-memory corev1client.NamespaceInterface backing
+// fakeNamespacesGetter below, used to integration-test finalize() against a
+// fake clientset.
+type fakeNamespaceClient struct {
+	corev1client.NamespaceInterface
+	namespace *corev1.Namespace
+}
+
+func (f *fakeNamespaceClient) Get(name string, options metav1.GetOptions) (*corev1.Namespace, error) {
+	return f.namespace.DeepCopy(), nil
+}
+
+func (f *fakeNamespaceClient) Finalize(namespace *corev1.Namespace) (*corev1.Namespace, error) {
+	f.namespace = namespace.DeepCopy()
+	return f.namespace.DeepCopy(), nil
+}
+
+type fakeNamespacesGetter struct {
+	client *fakeNamespaceClient
+}
+
+func (f *fakeNamespacesGetter) Namespaces() corev1client.NamespaceInterface {
+	return f.client
+}
+
+type fakeDrainer struct {
+	name    string
+	drained bool
+}
+
+func (d *fakeDrainer) Name() string                        { return d.name }
+func (d *fakeDrainer) Drained(namespace string) (bool, error) { return d.drained, nil }
+
+func TestFinalizeRemovesFinalizerOnceDrained(t *testing.T) {
+	now := metav1.Now()
+	client := &fakeNamespaceClient{namespace: &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns1", DeletionTimestamp: &now},
+		Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{proxy.OriginFinalizer}},
+	}}
+	c := &NamespaceFinalizerController{
+		client:   &fakeNamespacesGetter{client: client},
+		drainers: []ResourceDrainer{&fakeDrainer{name: "buildconfigs", drained: true}},
+	}
+
+	if err := c.finalize("ns1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proxy.HasFinalizer(client.namespace.Spec.Finalizers, proxy.OriginFinalizer) {
+		t.Errorf("expected OriginFinalizer to be removed once every drainer reports clean")
+	}
+}
+
+func TestFinalizeWaitsOnUndrainedResources(t *testing.T) {
+	now := metav1.Now()
+	client := &fakeNamespaceClient{namespace: &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns1", DeletionTimestamp: &now},
+		Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{proxy.OriginFinalizer}},
+	}}
+	c := &NamespaceFinalizerController{
+		client:   &fakeNamespacesGetter{client: client},
+		drainers: []ResourceDrainer{&fakeDrainer{name: "buildconfigs", drained: false}},
+	}
+
+	if err := c.finalize("ns1"); err == nil {
+		t.Fatalf("expected finalize to report an error while a drainer still has resources")
+	}
+	if !proxy.HasFinalizer(client.namespace.Spec.Finalizers, proxy.OriginFinalizer) {
+		t.Errorf("expected OriginFinalizer to remain while drainers are unfinished")
+	}
+}
+
+func TestFinalizeIgnoresNonTerminatingNamespace(t *testing.T) {
+	client := &fakeNamespaceClient{namespace: &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns1"},
+		Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{proxy.OriginFinalizer}},
+	}}
+	c := &NamespaceFinalizerController{
+		client:   &fakeNamespacesGetter{client: client},
+		drainers: []ResourceDrainer{&fakeDrainer{name: "buildconfigs", drained: true}},
+	}
+
+	if err := c.finalize("ns1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !proxy.HasFinalizer(client.namespace.Spec.Finalizers, proxy.OriginFinalizer) {
+		t.Errorf("expected a namespace that isn't terminating to be left alone")
+	}
+}
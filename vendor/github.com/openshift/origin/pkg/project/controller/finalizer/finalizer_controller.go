@@ -0,0 +1,146 @@
+package finalizer
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/openshift/origin/pkg/project/apiserver/registry/project/proxy"
+)
+
+// ResourceDrainer reports whether project-scoped resources of one kind still
+// exist in a namespace. The finalizer controller only removes
+// proxy.OriginFinalizer once every registered drainer reports the namespace
+// empty. Implementations typically wrap a single resource's typed client
+// (BuildConfigs, ImageStreams, RoleBindings, etc.).
+type ResourceDrainer interface {
+	// Name identifies the drainer in log messages and errors.
+	Name() string
+	// Drained returns true once no project-scoped resources of this kind
+	// remain in namespace.
+	Drained(namespace string) (bool, error)
+}
+
+// NamespaceFinalizerController removes proxy.OriginFinalizer from a
+// terminating Namespace once every ResourceDrainer reports the namespace
+// clear of project-scoped OpenShift resources.
+type NamespaceFinalizerController struct {
+	client   corev1client.NamespacesGetter
+	informer cache.SharedIndexInformer
+	drainers []ResourceDrainer
+	queue    workqueue.RateLimitingInterface
+}
+
+// NewNamespaceFinalizerController returns a controller driven by
+// nsInformer that finalizes terminating namespaces once every drainer has
+// confirmed its resources are gone.
+func NewNamespaceFinalizerController(nsInformer v1.NamespaceInformer, client corev1client.NamespacesGetter, drainers ...ResourceDrainer) *NamespaceFinalizerController {
+	c := &NamespaceFinalizerController{
+		client:   client,
+		informer: nsInformer.Informer(),
+		drainers: drainers,
+		queue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "namespace-finalizer"),
+	}
+	c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	return c
+}
+
+func (c *NamespaceFinalizerController) enqueue(obj interface{}) {
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+	if namespace.DeletionTimestamp == nil {
+		return
+	}
+	if !proxy.HasFinalizer(namespace.Spec.Finalizers, proxy.OriginFinalizer) {
+		return
+	}
+	c.queue.Add(namespace.Name)
+}
+
+// Run starts workers processing the queue until stopCh is closed.
+func (c *NamespaceFinalizerController) Run(workers int, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	if !cache.WaitForCacheSync(stopCh, c.informer.HasSynced) {
+		return
+	}
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, 0, stopCh)
+	}
+	<-stopCh
+}
+
+func (c *NamespaceFinalizerController) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *NamespaceFinalizerController) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.finalize(key.(string)); err != nil {
+		runtime.HandleError(fmt.Errorf("finalizing namespace %s: %v", key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+// finalize removes proxy.OriginFinalizer from name once every ResourceDrainer
+// reports the namespace clear. It re-queues (via a returned error) rather
+// than removing the finalizer when any drainer still finds resources.
+func (c *NamespaceFinalizerController) finalize(name string) error {
+	namespace, err := c.client.Namespaces().Get(name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if namespace.DeletionTimestamp == nil || !proxy.HasFinalizer(namespace.Spec.Finalizers, proxy.OriginFinalizer) {
+		return nil
+	}
+
+	for _, drainer := range c.drainers {
+		drained, err := drainer.Drained(name)
+		if err != nil {
+			return fmt.Errorf("%s: %v", drainer.Name(), err)
+		}
+		if !drained {
+			return fmt.Errorf("%s still has resources in namespace %s", drainer.Name(), name)
+		}
+	}
+
+	namespace.Spec.Finalizers = removeFinalizer(namespace.Spec.Finalizers, proxy.OriginFinalizer)
+	_, err = c.client.Namespaces().Finalize(namespace)
+	return err
+}
+
+func removeFinalizer(finalizers []corev1.FinalizerName, finalizer corev1.FinalizerName) []corev1.FinalizerName {
+	result := make([]corev1.FinalizerName, 0, len(finalizers))
+	for _, f := range finalizers {
+		if f != finalizer {
+			result = append(result, f)
+		}
+	}
+	return result
+}
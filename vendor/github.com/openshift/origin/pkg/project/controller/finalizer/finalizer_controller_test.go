@@ -0,0 +1,22 @@
+package finalizer
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/origin/pkg/project/apiserver/registry/project/proxy"
+)
+
+func TestRemoveFinalizer(t *testing.T) {
+	finalizers := []corev1.FinalizerName{proxy.OriginFinalizer, "other"}
+
+	result := removeFinalizer(finalizers, proxy.OriginFinalizer)
+	if len(result) != 1 || result[0] != "other" {
+		t.Errorf("expected only the non-matching finalizer to remain, got %v", result)
+	}
+
+	if proxy.HasFinalizer(result, proxy.OriginFinalizer) {
+		t.Errorf("expected OriginFinalizer to be gone after removeFinalizer")
+	}
+}